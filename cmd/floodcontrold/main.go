@@ -0,0 +1,69 @@
+// Command floodcontrold запускает flood-control-task как сервис: gRPC на
+// -grpc-addr и HTTP (/healthz, /metrics) на -http-addr. В отличие от
+// корневого main.go это то, что реально разворачивается перед Telegram-ботом
+// или REST API (см. internal/server).
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"flood-control-task/internal/floodcontrol"
+	"flood-control-task/internal/server"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	var (
+		interval    = flag.Duration("interval", 10*time.Second, "окно ограничения (N)")
+		maxRequests = flag.Int("max-requests", 5, "максимум запросов за окно (K)")
+		grpcAddr    = flag.String("grpc-addr", ":9090", "адрес gRPC-сервера")
+		httpAddr    = flag.String("http-addr", ":8080", "адрес HTTP-сервера (/healthz, /metrics)")
+
+		redisAddr = flag.String("redis-addr", "", "адрес Redis (host:port) для общего состояния между репликами; пусто — хранить в памяти процесса")
+
+		cbEnabled     = flag.Bool("circuit-breaker", false, "оборачивать FloodControl автоматом защиты от деградации downstream")
+		cbThreshold   = flag.Float64("cb-failure-threshold", 0.5, "доля ошибок downstream, после которой автомат размыкается")
+		cbMinRequests = flag.Int("cb-min-requests", 10, "минимум наблюдений, прежде чем учитывать долю ошибок")
+		cbCooldown    = flag.Duration("cb-cooldown", 30*time.Second, "сколько ждать в открытом состоянии до пробного запроса")
+		cbProbeQuota  = flag.Int("cb-probe-quota", 1, "сколько пробных запросов пропускать в полуоткрытом состоянии")
+	)
+	flag.Parse()
+
+	var fcOpts []floodcontrol.Option
+	if *redisAddr != "" {
+		log.Printf("floodcontrold: состояние флуд-контроля в Redis на %s", *redisAddr)
+		fcOpts = append(fcOpts, floodcontrol.WithRedisStorage(redis.NewClient(&redis.Options{Addr: *redisAddr})))
+	} else {
+		log.Print("floodcontrold: состояние флуд-контроля в памяти процесса (не годится для нескольких реплик, см. -redis-addr)")
+	}
+
+	fc := floodcontrol.NewFloodControl(*interval, *maxRequests, fcOpts...)
+	defer fc.Close()
+
+	var checker server.Checker = fc
+	if *cbEnabled {
+		checker = floodcontrol.WithCircuitBreaker(fc, floodcontrol.NewCircuitBreaker(
+			*cbThreshold, *cbMinRequests, *cbCooldown, *cbProbeQuota,
+		))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := &server.Server{
+		GRPCAddr: *grpcAddr,
+		HTTPAddr: *httpAddr,
+		Checker:  checker,
+	}
+
+	log.Printf("floodcontrold: gRPC на %s, HTTP на %s", *grpcAddr, *httpAddr)
+	if err := srv.Run(ctx); err != nil {
+		log.Fatalf("floodcontrold: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package floodcontrol
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerState — текущее состояние автомата для одного пользователя.
+type CircuitBreakerState int
+
+const (
+	// StateClosed — запросы идут как обычно, ошибки downstream учитываются.
+	StateClosed CircuitBreakerState = iota
+	// StateOpen — downstream деградировал, запросы коротко замыкаются.
+	StateOpen
+	// StateHalfOpen — пробный период после cooldown: пропускаем немного
+	// запросов и по их результату решаем, закрыться обратно или открыться снова.
+	StateHalfOpen
+)
+
+// CircuitBreaker отслеживает долю ошибок downstream-сервиса по userID,
+// которую сообщает вызывающая сторона через Report, и переключается
+// closed -> open -> half-open -> closed. Сам по себе не знает о лимитах
+// FloodControl — используется вместе с ним через CircuitBreakerFloodControl.
+// Состояние пользователей ограничено по числу (LRU-вытеснение) и чистится
+// фоновым sweeper'ом идлящих пользователей — как memoryStorage для
+// StrategySlidingLog (см. storage.go), чтобы states не рос неограниченно.
+type CircuitBreaker struct {
+	mu sync.Mutex
+
+	states *boundedUserMap[*breakerState]
+
+	failureThreshold float64       // доля ошибок (0..1), после которой размыкаемся
+	minRequests      int           // минимум наблюдений в закрытом состоянии, прежде чем считать долю
+	cooldown         time.Duration // сколько ждать в open, прежде чем дать пробный запрос
+	probeQuota       int           // сколько запросов пропустить в half-open
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type breakerState struct {
+	state      CircuitBreakerState
+	total      int
+	fails      int
+	openedAt   time.Time
+	probesLeft int
+}
+
+// NewCircuitBreaker создаёт размыкатель. failureThreshold — доля ошибок
+// (0..1) в окне из minRequests наблюдений, по достижении которой
+// пользователь переводится в StateOpen на cooldown; probeQuota — сколько
+// запросов пропускается в StateHalfOpen, прежде чем решение принимается
+// по первому же Report.
+func NewCircuitBreaker(failureThreshold float64, minRequests int, cooldown time.Duration, probeQuota int) *CircuitBreaker {
+	if probeQuota <= 0 {
+		probeQuota = 1
+	}
+	cb := &CircuitBreaker{
+		states:           newBoundedUserMap[*breakerState](0),
+		failureThreshold: failureThreshold,
+		minRequests:      minRequests,
+		cooldown:         cooldown,
+		probeQuota:       probeQuota,
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+	go sweeper(cb.stop, cb.done, defaultSweepInterval, cb.sweep)
+	return cb
+}
+
+func (cb *CircuitBreaker) stateFor(userID int64, now time.Time) *breakerState {
+	return *cb.states.getOrCreate(userID, now, func() *breakerState { return &breakerState{state: StateClosed} })
+}
+
+func (cb *CircuitBreaker) sweep(now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	// удаляем пользователей, не обращавшихся дольше cooldown. При следующем
+	// обращении такой пользователь начинает заново в StateClosed с нулевыми
+	// счётчиками — в том числе тот, чей автомат был разомкнут: пробного
+	// запроса в StateHalfOpen для него уже не будет.
+	cb.states.sweep(now, cb.cooldown)
+}
+
+// Close останавливает фоновый sweeper.
+func (cb *CircuitBreaker) Close() error {
+	close(cb.stop)
+	<-cb.done
+	return nil
+}
+
+// Allow сообщает, можно ли сейчас пропустить запрос userID к downstream.
+// В StateOpen после истечения cooldown сама переводит пользователя в
+// StateHalfOpen и выдаёт пробную квоту.
+func (cb *CircuitBreaker) Allow(userID int64, now time.Time) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	st := cb.stateFor(userID, now)
+
+	if st.state == StateOpen && now.Sub(st.openedAt) >= cb.cooldown {
+		st.state = StateHalfOpen
+		st.probesLeft = cb.probeQuota
+	}
+
+	switch st.state {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if st.probesLeft <= 0 {
+			return false
+		}
+		st.probesLeft--
+		return true
+	default: // StateClosed
+		return true
+	}
+}
+
+// Report сообщает автомату результат обращения к downstream для userID.
+// Вызывается только после Allow, который для этого запроса вернул true.
+func (cb *CircuitBreaker) Report(userID int64, ok bool, now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	st := cb.stateFor(userID, now)
+
+	switch st.state {
+	case StateHalfOpen:
+		if ok {
+			st.state = StateClosed
+			st.total, st.fails = 0, 0
+		} else {
+			st.state = StateOpen
+			st.openedAt = now
+		}
+	case StateClosed:
+		st.total++
+		if !ok {
+			st.fails++
+		}
+		if st.total < cb.minRequests {
+			return
+		}
+		if float64(st.fails)/float64(st.total) >= cb.failureThreshold {
+			st.state = StateOpen
+			st.openedAt = now
+			return
+		}
+		// окно набралось, деградации не видно — начинаем считать заново
+		st.total, st.fails = 0, 0
+	case StateOpen:
+		// открыт — пробные запросы решают дело через Allow+half-open, а не
+		// произвольные репорты, поэтому здесь ничего не меняем
+	}
+}
+
+// remainingCooldown сообщает, сколько ещё осталось ждать userID до пробного
+// запроса, если его автомат сейчас разомкнут; 0 в любом другом состоянии.
+// Используется CircuitBreakerFloodControl.Reserve как retryAfter при
+// коротком замыкании.
+func (cb *CircuitBreaker) remainingCooldown(userID int64, now time.Time) time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	st := cb.stateFor(userID, now)
+	if st.state != StateOpen {
+		return 0
+	}
+	remaining := cb.cooldown - now.Sub(st.openedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Reserver — то немногое, что CircuitBreakerFloodControl требует от
+// оборачиваемого FloodControl сверх Check: ту же richer-реплику, которую
+// отдаёт наружу server.Checker (см. internal/server).
+type Reserver interface {
+	FloodControl
+	Reserve(ctx context.Context, userID int64) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// CircuitBreakerFloodControl оборачивает FloodControl автоматом защиты от
+// деградации downstream: пока размыкатель пользователя открыт, Check и
+// Reserve коротко замыкаются в false без обращения к обёрнутому FloodControl.
+type CircuitBreakerFloodControl struct {
+	fc      Reserver
+	breaker *CircuitBreaker
+}
+
+// WithCircuitBreaker комбинирует уже настроенный FloodControl (лимитер +
+// хранилище) с размыкателем деградации.
+func WithCircuitBreaker(fc Reserver, breaker *CircuitBreaker) *CircuitBreakerFloodControl {
+	return &CircuitBreakerFloodControl{fc: fc, breaker: breaker}
+}
+
+func (w *CircuitBreakerFloodControl) Check(ctx context.Context, userID int64) (bool, error) {
+	if !w.breaker.Allow(userID, time.Now()) {
+		return false, nil
+	}
+	return w.fc.Check(ctx, userID)
+}
+
+// Reserve — то же самое, что Check, но при коротком замыкании или отказе
+// обёрнутого FloodControl дополнительно сообщает, через сколько стоит
+// повторить попытку; отказ обёрнутого FloodControl при этом, как и в
+// FloodControlImpl.Reserve, возвращается без ошибки. Нужен, чтобы CircuitBreakerFloodControl можно было
+// передать как server.Checker (см. internal/server) — без него обёрнутый
+// в автомат FloodControl нельзя было бы развернуть как сервис.
+func (w *CircuitBreakerFloodControl) Reserve(ctx context.Context, userID int64) (bool, time.Duration, error) {
+	now := time.Now()
+	if !w.breaker.Allow(userID, now) {
+		return false, w.breaker.remainingCooldown(userID, now), nil
+	}
+	return w.fc.Reserve(ctx, userID)
+}
+
+// Report передаёт размыкателю результат вызова downstream-сервиса для
+// userID — в отличие от Check, вызывающая сторона обращается к этому
+// методу сама, FloodControl о downstream ничего не знает.
+func (w *CircuitBreakerFloodControl) Report(userID int64, ok bool) {
+	w.breaker.Report(userID, ok, time.Now())
+}
@@ -0,0 +1,117 @@
+package floodcontrol_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"flood-control-task/internal/floodcontrol"
+)
+
+func TestCircuitBreaker_OpensAfterFailures(t *testing.T) {
+	cb := floodcontrol.NewCircuitBreaker(0.5, 2, time.Minute, 1)
+	userID := int64(1)
+	now := time.Now()
+
+	if !cb.Allow(userID, now) {
+		t.Fatal("закрытый автомат должен пропускать запросы")
+	}
+	cb.Report(userID, false, now)
+
+	if !cb.Allow(userID, now) {
+		t.Fatal("после первой ошибки, пока не набрано minRequests=2 наблюдений, автомат не должен размыкаться")
+	}
+	cb.Report(userID, false, now)
+
+	if cb.Allow(userID, now) {
+		t.Fatal("после превышения failureThreshold автомат должен разомкнуться")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAfterCooldown(t *testing.T) {
+	cb := floodcontrol.NewCircuitBreaker(0.5, 1, time.Minute, 1)
+	userID := int64(2)
+	now := time.Now()
+
+	cb.Allow(userID, now)
+	cb.Report(userID, false, now) // открывается
+
+	if cb.Allow(userID, now.Add(time.Second)) {
+		t.Fatal("до истечения cooldown запросы не должны проходить")
+	}
+
+	if !cb.Allow(userID, now.Add(time.Minute)) {
+		t.Fatal("по истечении cooldown должен выдаваться пробный запрос")
+	}
+	if cb.Allow(userID, now.Add(time.Minute)) {
+		t.Fatal("пробная квота исчерпана, второй запрос не должен проходить")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenRecoversOnSuccess(t *testing.T) {
+	cb := floodcontrol.NewCircuitBreaker(0.5, 1, time.Minute, 1)
+	userID := int64(3)
+	now := time.Now()
+
+	cb.Allow(userID, now)
+	cb.Report(userID, false, now) // открывается
+
+	probeAt := now.Add(time.Minute)
+	if !cb.Allow(userID, probeAt) {
+		t.Fatal("пробный запрос должен пройти")
+	}
+	cb.Report(userID, true, probeAt) // успешный пробный запрос закрывает автомат
+
+	if !cb.Allow(userID, probeAt) {
+		t.Fatal("после успешного пробного запроса автомат должен закрыться")
+	}
+}
+
+func TestCircuitBreakerFloodControl_ShortCircuits(t *testing.T) {
+	fc := floodcontrol.NewFloodControl(time.Minute, 100)
+	cb := floodcontrol.NewCircuitBreaker(0.5, 1, time.Minute, 1)
+	wrapped := floodcontrol.WithCircuitBreaker(fc, cb)
+
+	userID := int64(4)
+
+	ok, err := wrapped.Check(context.Background(), userID)
+	if err != nil || !ok {
+		t.Fatalf("первый запрос должен пройти: ok=%v err=%v", ok, err)
+	}
+
+	wrapped.Report(userID, false)
+
+	ok, err = wrapped.Check(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("короткое замыкание не должно возвращать ошибку: %v", err)
+	}
+	if ok {
+		t.Fatal("после открытия автомата Check должен коротко замыкаться в false")
+	}
+}
+
+func TestCircuitBreakerFloodControl_ReserveShortCircuits(t *testing.T) {
+	fc := floodcontrol.NewFloodControl(time.Minute, 100)
+	cb := floodcontrol.NewCircuitBreaker(0.5, 1, time.Minute, 1)
+	wrapped := floodcontrol.WithCircuitBreaker(fc, cb)
+
+	userID := int64(5)
+
+	ok, _, err := wrapped.Reserve(context.Background(), userID)
+	if err != nil || !ok {
+		t.Fatalf("первый запрос должен пройти: ok=%v err=%v", ok, err)
+	}
+
+	wrapped.Report(userID, false)
+
+	ok, retryAfter, err := wrapped.Reserve(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("короткое замыкание не должно возвращать ошибку: %v", err)
+	}
+	if ok {
+		t.Fatal("после открытия автомата Reserve должен коротко замыкаться в false")
+	}
+	if retryAfter <= 0 {
+		t.Error("короткое замыкание должно возвращать положительный retryAfter до конца cooldown")
+	}
+}
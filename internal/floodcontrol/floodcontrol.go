@@ -0,0 +1,212 @@
+// Package floodcontrol ограничивает частоту запросов пользователя: не более
+// maxRequests обращений за timeInterval. Алгоритм ограничения (Limiter) и
+// место хранения истории запросов (Storage) подключаются отдельно, поэтому
+// один и тот же FloodControlImpl одинаково годится и для однопроцессного
+// CLI (см. корневой main.go), и для сервиса с несколькими репликами
+// (см. internal/server), делящими состояние через Redis. QuotaResolver
+// позволяет дать разным пользователям разные N/K (см. WithQuotaResolver).
+package floodcontrol
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Изначальный интерфейс
+type FloodControl interface {
+	Check(ctx context.Context, userID int64) (bool, error)
+}
+
+// реализация интерфейса FloodControl. Сама по себе ничего не хранит —
+// вся логика ограничения вынесена в подключаемую стратегию Limiter
+// (см. limiter.go), а состояние StrategySlidingLog — в подключаемый
+// Storage (см. storage.go), что позволяет делить его между репликами.
+type FloodControlImpl struct {
+	limiter      Limiter
+	storage      Storage
+	strategy     Strategy      // нужна, чтобы лениво строить лимитеры под тарифы, см. limiterFor
+	timeInterval time.Duration // N, нужно WithStrategy, чтобы построить Limiter с теми же параметрами
+	maxRequests  int           // K
+
+	quotaResolver QuotaResolver // задаёт N/K по userID вместо общих timeInterval/maxRequests, см. WithQuotaResolver
+
+	tierMu       sync.Mutex
+	tierLimiters map[tierKey]Limiter // лимитеры под отдельные (N, K), создаются лениво в limiterFor
+}
+
+// Option настраивает FloodControlImpl при создании.
+type Option func(*FloodControlImpl)
+
+// QuotaResolver определяет лимит K и окно N для конкретного userID —
+// например, чтобы дать free/paid/admin пользователям разные квоты поверх
+// одного и того же FloodControlImpl (см. WithQuotaResolver).
+type QuotaResolver func(userID int64) (limit int, window time.Duration)
+
+// tierKey — ключ кеша лимитеров под конкретную пару (N, K) из QuotaResolver.
+type tierKey struct {
+	limit  int
+	window time.Duration
+}
+
+// defaultRedisKeyPrefix — префикс ключей Redis, если явный не передан в WithRedisStorage.
+const defaultRedisKeyPrefix = "flood_control"
+
+// WithStrategy выбирает алгоритм ограничения (по умолчанию — StrategySlidingLog,
+// как было в исходной реализации).
+func WithStrategy(strategy Strategy) Option {
+	return func(fc *FloodControlImpl) {
+		fc.strategy = strategy
+		fc.limiter = newLimiter(strategy, fc.timeInterval, fc.maxRequests, fc.storage)
+	}
+}
+
+// WithLimiter подставляет уже готовую стратегию, например для тестов
+// или для случая, когда ни одна из встроенных не подходит.
+func WithLimiter(limiter Limiter) Option {
+	return func(fc *FloodControlImpl) {
+		fc.limiter = limiter
+	}
+}
+
+// WithStorage задаёт хранилище истории запросов для StrategySlidingLog —
+// например, реализацию поверх Redis, чтобы несколько реплик сервиса
+// делили одно и то же состояние. Должна применяться до WithStrategy:
+// сама она всегда ставит Limiter стратегии StrategySlidingLog и тем самым
+// заменяет Limiter, выбранный WithStrategy раньше.
+func WithStorage(storage Storage) Option {
+	return func(fc *FloodControlImpl) {
+		fc.replaceStorage(storage)
+		fc.limiter = newLimiter(StrategySlidingLog, fc.timeInterval, fc.maxRequests, storage)
+	}
+}
+
+// WithRedisStorage — удобная обёртка над WithStorage для самого частого случая:
+// общее состояние флуд-контроля в Redis.
+func WithRedisStorage(client *redis.Client) Option {
+	return WithStorage(newRedisStorage(client, defaultRedisKeyPrefix))
+}
+
+// WithMaxUsers ограничивает memoryStorage так, чтобы он помнил не больше
+// maxUsers пользователей одновременно (LRU-вытеснение), вместо
+// defaultMaxUsers. Как и WithStorage, действует только на StrategySlidingLog
+// и должна применяться до WithStrategy.
+func WithMaxUsers(maxUsers int) Option {
+	return func(fc *FloodControlImpl) {
+		storage := newMemoryStorage(maxUsers)
+		fc.replaceStorage(storage)
+		fc.limiter = newLimiter(StrategySlidingLog, fc.timeInterval, fc.maxRequests, storage)
+	}
+}
+
+// WithQuotaResolver включает тарифные квоты: resolver вызывается на каждый
+// Check/Reserve и определяет N и K для конкретного userID вместо общих
+// timeInterval/maxRequests, переданных в NewFloodControl. Лимитеры под
+// встречающиеся пары (N, K) создаются лениво и переиспользуются, поэтому
+// количество тарифов не обязано быть известно заранее.
+func WithQuotaResolver(resolver QuotaResolver) Option {
+	return func(fc *FloodControlImpl) {
+		fc.quotaResolver = resolver
+	}
+}
+
+// replaceStorage закрывает прежнее хранилище (если оно есть и отличается от
+// нового) перед тем, как подставить новое. Без этого дефолтный memoryStorage,
+// созданный в NewFloodControl до применения опций, остаётся работать в фоне
+// (его sweeper-горутина) даже после того, как WithStorage/WithRedisStorage/
+// WithMaxUsers подставят другое хранилище — fc.Close() закрывает только
+// текущее fc.storage и никогда до него не доберётся.
+func (fc *FloodControlImpl) replaceStorage(storage Storage) {
+	if fc.storage != nil && fc.storage != storage {
+		fc.storage.Close()
+	}
+	fc.storage = storage
+}
+
+// NewFloodControl создаёт новый флудконтрол.
+func NewFloodControl(timeInterval time.Duration, maxRequests int, opts ...Option) *FloodControlImpl {
+	fc := &FloodControlImpl{
+		timeInterval: timeInterval,
+		maxRequests:  maxRequests,
+		storage:      newMemoryStorage(0),
+		strategy:     StrategySlidingLog,
+	}
+	fc.limiter = newLimiter(fc.strategy, timeInterval, maxRequests, fc.storage)
+	for _, opt := range opts {
+		opt(fc)
+	}
+	return fc
+}
+
+// limiterFor возвращает лимитер для userID: без QuotaResolver — общий
+// fc.limiter, с ним — лимитер под персональную пару (N, K), построенный при
+// первом обращении и закешированный в tierLimiters.
+func (fc *FloodControlImpl) limiterFor(userID int64) Limiter {
+	if fc.quotaResolver == nil {
+		return fc.limiter
+	}
+
+	limit, window := fc.quotaResolver(userID)
+	key := tierKey{limit: limit, window: window}
+
+	fc.tierMu.Lock()
+	defer fc.tierMu.Unlock()
+
+	if limiter, ok := fc.tierLimiters[key]; ok {
+		return limiter
+	}
+	if fc.tierLimiters == nil {
+		fc.tierLimiters = make(map[tierKey]Limiter)
+	}
+	limiter := newLimiter(fc.strategy, window, limit, fc.storage)
+	fc.tierLimiters[key] = limiter
+	return limiter
+}
+
+func (fc *FloodControlImpl) Check(ctx context.Context, userID int64) (bool, error) {
+	allowed, err := fc.limiterFor(userID).Check(ctx, userID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		return false, errors.New("превышено максимальное количество запросов") // запросов >= K
+	}
+	return true, nil
+}
+
+// Reserve — то же самое, что Check, но при отказе возвращает не ошибку, а
+// время, через которое стоит повторить запрос.
+func (fc *FloodControlImpl) Reserve(ctx context.Context, userID int64) (bool, time.Duration, error) {
+	allowed, retryAfter, err := fc.limiterFor(userID).Reserve(ctx, userID, time.Now())
+	if err != nil {
+		return false, 0, err
+	}
+	return allowed, retryAfter, nil
+}
+
+// Close освобождает ресурсы хранилища (соединения с Redis и т. п.) и
+// останавливает фоновые sweeper'ы лимитеров (см. limiter.go), в том числе
+// тарифных из tierLimiters, чтобы долгоживущий сервис мог корректно
+// завершить работу.
+func (fc *FloodControlImpl) Close() error {
+	closeLimiter(fc.limiter)
+
+	fc.tierMu.Lock()
+	for _, limiter := range fc.tierLimiters {
+		closeLimiter(limiter)
+	}
+	fc.tierMu.Unlock()
+
+	return fc.storage.Close()
+}
+
+// closeLimiter останавливает фоновый sweeper лимитера, если тот его
+// запускает (не все стратегии это делают — см. limiter.go).
+func closeLimiter(limiter Limiter) {
+	if closer, ok := limiter.(interface{ Close() error }); ok {
+		closer.Close()
+	}
+}
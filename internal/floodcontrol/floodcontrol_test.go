@@ -0,0 +1,222 @@
+package floodcontrol_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"flood-control-task/internal/floodcontrol"
+)
+
+func TestFloodControlImpl_Check(t *testing.T) {
+	fc := floodcontrol.NewFloodControl(time.Second*10, 5)
+
+	// убедимся, что запросы от разных пользователей не влияют друг на друга
+	userID1 := int64(1)
+	userID2 := int64(2)
+
+	ok, err := fc.Check(context.Background(), userID1)
+	if err != nil {
+		t.Errorf("Ошибка при проверке первого запроса: %v", err)
+	}
+	if !ok {
+		t.Error("Первый запрос должен пройти успешно")
+	}
+
+	ok, err = fc.Check(context.Background(), userID2)
+	if err != nil {
+		t.Errorf("Ошибка при проверке второго запроса: %v", err)
+	}
+	if !ok {
+		t.Error("Второй запрос должен пройти успешно")
+	}
+
+	// проверка, что превышение максимального количества запросов возвращает ошибку
+	for i := 0; i < 5; i++ {
+		fc.Check(context.Background(), userID1)
+	}
+
+	ok, _ = fc.Check(context.Background(), userID1)
+	if ok {
+		t.Error("Превышение максимального количества запросов должно вернуть ошибку")
+	}
+	ok, err = fc.Check(context.Background(), userID2)
+	if err != nil {
+		t.Errorf("Ошибка при проверке второго запроса: %v", err)
+	}
+	if !ok {
+		t.Error("Второй запрос должен пройти успешно")
+	}
+}
+
+func TestFloodControlImpl_Strategies(t *testing.T) {
+	strategies := []floodcontrol.Strategy{
+		floodcontrol.StrategySlidingLog,
+		floodcontrol.StrategyFixedWindow,
+		floodcontrol.StrategySlidingWindow,
+		floodcontrol.StrategyLeakyBucket,
+		floodcontrol.StrategyTokenBucket,
+	}
+
+	for _, strategy := range strategies {
+		fc := floodcontrol.NewFloodControl(time.Second*10, 3, floodcontrol.WithStrategy(strategy))
+		userID := int64(42)
+
+		allowed := 0
+		for i := 0; i < 3; i++ {
+			ok, err := fc.Check(context.Background(), userID)
+			if err != nil {
+				t.Fatalf("стратегия %v: неожиданная ошибка: %v", strategy, err)
+			}
+			if ok {
+				allowed++
+			}
+		}
+		if allowed == 0 {
+			t.Errorf("стратегия %v: ни один из первых 3 запросов не прошёл", strategy)
+		}
+
+		ok, retryAfter, err := fc.Reserve(context.Background(), userID)
+		if err != nil {
+			t.Fatalf("стратегия %v: неожиданная ошибка в Reserve: %v", strategy, err)
+		}
+		if ok && retryAfter != 0 {
+			t.Errorf("стратегия %v: разрешённый запрос не должен иметь retryAfter", strategy)
+		}
+	}
+}
+
+func TestMemoryStorage_CheckAndAdd(t *testing.T) {
+	fc := floodcontrol.NewFloodControl(time.Second*10, 3)
+	userID := int64(7)
+
+	for i := 0; i < 3; i++ {
+		ok, err := fc.Check(context.Background(), userID)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !ok {
+			t.Errorf("запрос %d должен пройти", i)
+		}
+	}
+
+	ok, _ := fc.Check(context.Background(), userID)
+	if ok {
+		t.Error("четвёртый запрос должен быть отклонён")
+	}
+
+	_, retryAfter, err := fc.Reserve(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка в Reserve: %v", err)
+	}
+	if retryAfter <= 0 {
+		t.Error("отклонённый запрос должен вернуть положительный retryAfter")
+	}
+}
+
+func TestMemoryStorage_LRUEviction(t *testing.T) {
+	fc := floodcontrol.NewFloodControl(time.Minute, 1, floodcontrol.WithMaxUsers(2))
+
+	user1, user2, user3 := int64(1), int64(2), int64(3)
+
+	ok, err := fc.Check(context.Background(), user1)
+	if err != nil || !ok {
+		t.Fatalf("первый запрос user1 должен пройти: ok=%v err=%v", ok, err)
+	}
+	ok, _ = fc.Check(context.Background(), user1)
+	if ok {
+		t.Fatal("второй запрос user1 должен быть отклонён лимитом")
+	}
+
+	ok, err = fc.Check(context.Background(), user2)
+	if err != nil || !ok {
+		t.Fatalf("первый запрос user2 должен пройти: ok=%v err=%v", ok, err)
+	}
+
+	// user3 — третий пользователь при maxUsers=2: вытесняет user1,
+	// как наименее недавно использованного.
+	ok, err = fc.Check(context.Background(), user3)
+	if err != nil || !ok {
+		t.Fatalf("первый запрос user3 должен пройти: ok=%v err=%v", ok, err)
+	}
+
+	ok, err = fc.Check(context.Background(), user1)
+	if err != nil || !ok {
+		t.Fatalf("после вытеснения user1 должен снова считаться новым: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestFloodControlImpl_QuotaResolver(t *testing.T) {
+	const adminID = int64(42)
+	resolver := func(userID int64) (int, time.Duration) {
+		if userID == adminID {
+			return 5, 10 * time.Second
+		}
+		return 1, 10 * time.Second
+	}
+	fc := floodcontrol.NewFloodControl(10*time.Second, 1, floodcontrol.WithQuotaResolver(resolver))
+
+	freeUser := int64(1)
+	ok, err := fc.Check(context.Background(), freeUser)
+	if err != nil || !ok {
+		t.Fatalf("первый запрос обычного пользователя должен пройти: ok=%v err=%v", ok, err)
+	}
+	ok, _ = fc.Check(context.Background(), freeUser)
+	if ok {
+		t.Fatal("второй запрос обычного пользователя должен быть отклонён его квотой (K=1)")
+	}
+
+	for i := 0; i < 5; i++ {
+		ok, err := fc.Check(context.Background(), adminID)
+		if err != nil || !ok {
+			t.Fatalf("запрос администратора %d должен пройти: ok=%v err=%v", i, ok, err)
+		}
+	}
+	ok, _ = fc.Check(context.Background(), adminID)
+	if ok {
+		t.Fatal("шестой запрос администратора должен быть отклонён его квотой (K=5)")
+	}
+}
+
+// numGoroutines даёт горутинам sweeper'ов, запущенным/остановленным в ходе
+// теста, время фактически завершиться, прежде чем считать их количество —
+// runtime.NumGoroutine() иначе может на мгновение показать ещё не
+// остановленные горутины.
+func numGoroutines(t *testing.T) int {
+	t.Helper()
+	runtime.Gosched()
+	time.Sleep(10 * time.Millisecond)
+	return runtime.NumGoroutine()
+}
+
+func TestFloodControlImpl_SlidingWindowTinyIntervalDoesNotPanic(t *testing.T) {
+	fc := floodcontrol.NewFloodControl(5*time.Nanosecond, 3, floodcontrol.WithStrategy(floodcontrol.StrategySlidingWindow))
+	defer fc.Close()
+
+	if _, err := fc.Check(context.Background(), int64(1)); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+}
+
+func TestNewFloodControl_OptionReplacingStorageDoesNotLeakDefault(t *testing.T) {
+	before := numGoroutines(t)
+
+	const n = 20
+	fcs := make([]*floodcontrol.FloodControlImpl, n)
+	for i := range fcs {
+		fcs[i] = floodcontrol.NewFloodControl(time.Minute, 5, floodcontrol.WithMaxUsers(10))
+	}
+	for _, fc := range fcs {
+		if err := fc.Close(); err != nil {
+			t.Fatalf("неожиданная ошибка в Close: %v", err)
+		}
+	}
+
+	after := numGoroutines(t)
+	if after > before {
+		t.Errorf("после Close() осталось %d лишних горутин (было %d, стало %d) — "+
+			"дефолтное хранилище, вытесненное WithMaxUsers, не было закрыто",
+			after-before, before, after)
+	}
+}
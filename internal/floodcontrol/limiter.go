@@ -0,0 +1,458 @@
+package floodcontrol
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Limiter — стратегия ограничения частоты запросов, общая для всех
+// пользователей. Реализация сама отвечает за состояние конкретного
+// userID и за его синхронизацию. ctx пробрасывается до Storage
+// (см. storage.go) там, где состояние лежит не в памяти процесса.
+type Limiter interface {
+	// Check сообщает, можно ли пропустить запрос userID в момент now,
+	// и, если да, учитывает этот запрос в своём состоянии.
+	Check(ctx context.Context, userID int64, now time.Time) (bool, error)
+	// Reserve делает то же самое, что и Check, но если запрос отклонён,
+	// дополнительно возвращает время, через которое стоит повторить попытку.
+	Reserve(ctx context.Context, userID int64, now time.Time) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Strategy — выбор алгоритма ограничения, передаваемый в NewFloodControl.
+type Strategy int
+
+const (
+	// StrategySlidingLog — поведение по умолчанию: храним точное время
+	// каждого запроса и отбрасываем те, что старше timeInterval.
+	StrategySlidingLog Strategy = iota
+	// StrategyFixedWindow — считаем запросы в пределах текущего окна
+	// фиксированной длины timeInterval, без сглаживания границы окна.
+	StrategyFixedWindow
+	// StrategySlidingWindow — делит окно на N под-интервалов и суммирует
+	// запросы по ним, сглаживая проблему границы окна.
+	StrategySlidingWindow
+	// StrategyLeakyBucket — бакет постоянно "протекает" с фиксированной
+	// скоростью, запрос проходит, если в бакете есть свободное место.
+	StrategyLeakyBucket
+	// StrategyTokenBucket — бакет пополняется токенами с постоянной
+	// скоростью и позволяет короткие всплески до его вместимости.
+	StrategyTokenBucket
+)
+
+var errNotTime = errors.New("в очереди хранится не время")
+
+// newLimiter создаёт Limiter для выбранной стратегии. timeInterval — это N,
+// maxRequests — это K в терминах исходного ТЗ (не более K запросов за N).
+// storage используется только StrategySlidingLog — остальные стратегии пока
+// живут в памяти процесса (см. storage.go).
+func newLimiter(strategy Strategy, timeInterval time.Duration, maxRequests int, storage Storage) Limiter {
+	switch strategy {
+	case StrategyFixedWindow:
+		return newFixedWindowLimiter(timeInterval, maxRequests)
+	case StrategySlidingWindow:
+		return newSlidingWindowLimiter(timeInterval, maxRequests, defaultSlidingWindowBuckets)
+	case StrategyLeakyBucket:
+		return newLeakyBucketLimiter(timeInterval, maxRequests)
+	case StrategyTokenBucket:
+		return newTokenBucketLimiter(timeInterval, maxRequests)
+	default:
+		return newSlidingLogLimiter(timeInterval, maxRequests, storage)
+	}
+}
+
+// slidingLogLimiter — исходная реализация: для каждого пользователя хранится
+// точное время каждого запроса. Само хранение вынесено в Storage, чтобы
+// несколько реплик сервиса могли делить одно и то же состояние (см. storage.go).
+type slidingLogLimiter struct {
+	storage      Storage
+	timeInterval time.Duration
+	maxRequests  int
+}
+
+func newSlidingLogLimiter(timeInterval time.Duration, maxRequests int, storage Storage) *slidingLogLimiter {
+	if storage == nil {
+		storage = newMemoryStorage(0)
+	}
+	return &slidingLogLimiter{
+		storage:      storage,
+		timeInterval: timeInterval,
+		maxRequests:  maxRequests,
+	}
+}
+
+func (l *slidingLogLimiter) Check(ctx context.Context, userID int64, now time.Time) (bool, error) {
+	allowed, err := l.storage.CheckAndAdd(ctx, userID, now, l.timeInterval, l.maxRequests)
+	if err != nil {
+		return false, err
+	}
+	return allowed, nil
+}
+
+func (l *slidingLogLimiter) Reserve(ctx context.Context, userID int64, now time.Time) (bool, time.Duration, error) {
+	allowed, err := l.storage.CheckAndAdd(ctx, userID, now, l.timeInterval, l.maxRequests)
+	if err != nil {
+		return false, 0, err
+	}
+	if allowed {
+		return true, 0, nil
+	}
+
+	oldest, ok, err := l.storage.Oldest(ctx, userID)
+	if err != nil {
+		return false, 0, err
+	}
+	if !ok {
+		return false, 0, nil
+	}
+
+	retryAfter := l.timeInterval - now.Sub(oldest)
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return false, retryAfter, nil
+}
+
+// defaultSlidingWindowBuckets — количество под-интервалов, на которые режется
+// окно в StrategySlidingWindow.
+const defaultSlidingWindowBuckets = 10
+
+// fixedWindowLimiter — считает запросы в пределах текущего окна
+// фиксированной длины, окно целиком сбрасывается по истечении timeInterval.
+// Состояние пользователей ограничено по числу (LRU-вытеснение) и чистится
+// фоновым sweeper'ом идлящих пользователей — как memoryStorage для
+// StrategySlidingLog (см. storage.go), чтобы windows не рос неограниченно.
+type fixedWindowLimiter struct {
+	mu           sync.Mutex
+	windows      *boundedUserMap[*fixedWindowState]
+	timeInterval time.Duration
+	maxRequests  int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type fixedWindowState struct {
+	windowStart time.Time
+	count       int
+}
+
+func newFixedWindowLimiter(timeInterval time.Duration, maxRequests int) *fixedWindowLimiter {
+	l := &fixedWindowLimiter{
+		windows:      newBoundedUserMap[*fixedWindowState](0),
+		timeInterval: timeInterval,
+		maxRequests:  maxRequests,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go sweeper(l.stop, l.done, defaultSweepInterval, l.sweep)
+	return l
+}
+
+func (l *fixedWindowLimiter) Check(ctx context.Context, userID int64, now time.Time) (bool, error) {
+	allowed, _, err := l.Reserve(ctx, userID, now)
+	return allowed, err
+}
+
+func (l *fixedWindowLimiter) Reserve(ctx context.Context, userID int64, now time.Time) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := *l.windows.getOrCreate(userID, now, func() *fixedWindowState { return &fixedWindowState{} })
+	if state.windowStart.IsZero() || now.Sub(state.windowStart) >= l.timeInterval {
+		state.windowStart = now
+		state.count = 0
+	}
+
+	if state.count >= l.maxRequests {
+		retryAfter := l.timeInterval - now.Sub(state.windowStart)
+		if retryAfter < 0 {
+			retryAfter = 0
+		}
+		return false, retryAfter, nil
+	}
+
+	state.count++
+	return true, 0, nil
+}
+
+func (l *fixedWindowLimiter) sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.windows.sweep(now, l.timeInterval)
+}
+
+// Close останавливает фоновый sweeper. FloodControlImpl.Close вызывает его
+// для лимитера и всех тарифных лимитеров из tierLimiters (см. floodcontrol.go).
+func (l *fixedWindowLimiter) Close() error {
+	close(l.stop)
+	<-l.done
+	return nil
+}
+
+// slidingWindowLimiter режет окно на buckets под-интервалов и суммирует
+// количество запросов по ним, тем самым сглаживая проблему границы окна,
+// характерную для fixedWindowLimiter. Состояние пользователей ограничено
+// по числу (LRU-вытеснение) и чистится фоновым sweeper'ом идлящих
+// пользователей — как memoryStorage для StrategySlidingLog (см. storage.go).
+type slidingWindowLimiter struct {
+	mu           sync.Mutex
+	users        *boundedUserMap[*slidingWindowState]
+	timeInterval time.Duration
+	maxRequests  int
+	buckets      int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type slidingWindowState struct {
+	counts      []int
+	bucketStart []time.Time
+}
+
+func newSlidingWindowLimiter(timeInterval time.Duration, maxRequests, buckets int) *slidingWindowLimiter {
+	if buckets <= 0 {
+		buckets = defaultSlidingWindowBuckets
+	}
+	l := &slidingWindowLimiter{
+		users:        newBoundedUserMap[*slidingWindowState](0),
+		timeInterval: timeInterval,
+		maxRequests:  maxRequests,
+		buckets:      buckets,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go sweeper(l.stop, l.done, defaultSweepInterval, l.sweep)
+	return l
+}
+
+// bucketWidth делит timeInterval на l.buckets под-интервалов. Если
+// timeInterval настолько мал относительно числа бакетов, что целочисленное
+// деление даёт 0, используем 1ns — иначе индекс бакета в Reserve делится на
+// него и падает с "integer divide by zero".
+func (l *slidingWindowLimiter) bucketWidth() time.Duration {
+	width := l.timeInterval / time.Duration(l.buckets)
+	if width <= 0 {
+		width = time.Nanosecond
+	}
+	return width
+}
+
+func (l *slidingWindowLimiter) Check(ctx context.Context, userID int64, now time.Time) (bool, error) {
+	allowed, _, err := l.Reserve(ctx, userID, now)
+	return allowed, err
+}
+
+func (l *slidingWindowLimiter) Reserve(ctx context.Context, userID int64, now time.Time) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	width := l.bucketWidth()
+	state := *l.users.getOrCreate(userID, now, func() *slidingWindowState {
+		return &slidingWindowState{
+			counts:      make([]int, l.buckets),
+			bucketStart: make([]time.Time, l.buckets),
+		}
+	})
+
+	// обнуляем под-интервалы, которые уже выпали за пределы окна
+	total := 0
+	for i := range state.counts {
+		if state.bucketStart[i].IsZero() || now.Sub(state.bucketStart[i]) >= l.timeInterval {
+			state.counts[i] = 0
+			state.bucketStart[i] = time.Time{}
+			continue
+		}
+		total += state.counts[i]
+	}
+
+	if total >= l.maxRequests {
+		return false, width, nil
+	}
+
+	idx := int(now.UnixNano()/int64(width)) % l.buckets
+	if idx < 0 {
+		idx += l.buckets
+	}
+	if state.bucketStart[idx].IsZero() {
+		state.bucketStart[idx] = now
+	}
+	state.counts[idx]++
+
+	return true, 0, nil
+}
+
+func (l *slidingWindowLimiter) sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.users.sweep(now, l.timeInterval)
+}
+
+// Close останавливает фоновый sweeper.
+func (l *slidingWindowLimiter) Close() error {
+	close(l.stop)
+	<-l.done
+	return nil
+}
+
+// leakyBucketLimiter — бакет ёмкостью maxRequests, который "протекает" с
+// постоянной скоростью: один запрос уходит из бакета каждые
+// timeInterval/maxRequests. Состояние пользователей ограничено по числу
+// (LRU-вытеснение) и чистится фоновым sweeper'ом идлящих пользователей —
+// как memoryStorage для StrategySlidingLog (см. storage.go).
+type leakyBucketLimiter struct {
+	mu           sync.Mutex
+	buckets      *boundedUserMap[*leakyBucketState]
+	rate         time.Duration // время на "вытекание" одного запроса
+	capacity     int
+	timeInterval time.Duration // N, только для TTL sweeper'а (см. sweep)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type leakyBucketState struct {
+	level      float64 // текущее заполнение бакета
+	lastLeakAt time.Time
+}
+
+func newLeakyBucketLimiter(timeInterval time.Duration, capacity int) *leakyBucketLimiter {
+	rate := timeInterval
+	if capacity > 0 {
+		rate = timeInterval / time.Duration(capacity)
+	}
+	l := &leakyBucketLimiter{
+		buckets:      newBoundedUserMap[*leakyBucketState](0),
+		rate:         rate,
+		capacity:     capacity,
+		timeInterval: timeInterval,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go sweeper(l.stop, l.done, defaultSweepInterval, l.sweep)
+	return l
+}
+
+func (l *leakyBucketLimiter) Check(ctx context.Context, userID int64, now time.Time) (bool, error) {
+	allowed, _, err := l.Reserve(ctx, userID, now)
+	return allowed, err
+}
+
+func (l *leakyBucketLimiter) Reserve(ctx context.Context, userID int64, now time.Time) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := *l.buckets.getOrCreate(userID, now, func() *leakyBucketState { return &leakyBucketState{lastLeakAt: now} })
+
+	leaked := float64(now.Sub(state.lastLeakAt)) / float64(l.rate)
+	state.level -= leaked
+	if state.level < 0 {
+		state.level = 0
+	}
+	state.lastLeakAt = now
+
+	if state.level+1 > float64(l.capacity) {
+		overflow := state.level + 1 - float64(l.capacity)
+		retryAfter := time.Duration(overflow * float64(l.rate))
+		return false, retryAfter, nil
+	}
+
+	state.level++
+	return true, 0, nil
+}
+
+func (l *leakyBucketLimiter) sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buckets.sweep(now, l.timeInterval)
+}
+
+// Close останавливает фоновый sweeper.
+func (l *leakyBucketLimiter) Close() error {
+	close(l.stop)
+	<-l.done
+	return nil
+}
+
+// tokenBucketLimiter — бакет ёмкостью capacity пополняется на один токен
+// каждые timeInterval/capacity, позволяя короткие всплески до capacity
+// запросов с последующим ограничением скорости до capacity/timeInterval.
+// Состояние пользователей ограничено по числу (LRU-вытеснение) и чистится
+// фоновым sweeper'ом идлящих пользователей — как memoryStorage для
+// StrategySlidingLog (см. storage.go).
+type tokenBucketLimiter struct {
+	mu           sync.Mutex
+	buckets      *boundedUserMap[*tokenBucketState]
+	refillRate   time.Duration // время на пополнение одного токена
+	capacity     int
+	timeInterval time.Duration // N, только для TTL sweeper'а (см. sweep)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type tokenBucketState struct {
+	tokens       float64
+	lastRefillAt time.Time
+}
+
+func newTokenBucketLimiter(timeInterval time.Duration, capacity int) *tokenBucketLimiter {
+	refillRate := timeInterval
+	if capacity > 0 {
+		refillRate = timeInterval / time.Duration(capacity)
+	}
+	l := &tokenBucketLimiter{
+		buckets:      newBoundedUserMap[*tokenBucketState](0),
+		refillRate:   refillRate,
+		capacity:     capacity,
+		timeInterval: timeInterval,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go sweeper(l.stop, l.done, defaultSweepInterval, l.sweep)
+	return l
+}
+
+func (l *tokenBucketLimiter) Check(ctx context.Context, userID int64, now time.Time) (bool, error) {
+	allowed, _, err := l.Reserve(ctx, userID, now)
+	return allowed, err
+}
+
+func (l *tokenBucketLimiter) Reserve(ctx context.Context, userID int64, now time.Time) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := *l.buckets.getOrCreate(userID, now, func() *tokenBucketState {
+		return &tokenBucketState{tokens: float64(l.capacity), lastRefillAt: now}
+	})
+
+	refilled := float64(now.Sub(state.lastRefillAt)) / float64(l.refillRate)
+	state.tokens += refilled
+	if state.tokens > float64(l.capacity) {
+		state.tokens = float64(l.capacity)
+	}
+	state.lastRefillAt = now
+
+	if state.tokens < 1 {
+		retryAfter := time.Duration((1 - state.tokens) * float64(l.refillRate))
+		return false, retryAfter, nil
+	}
+
+	state.tokens--
+	return true, 0, nil
+}
+
+func (l *tokenBucketLimiter) sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buckets.sweep(now, l.timeInterval)
+}
+
+// Close останавливает фоновый sweeper.
+func (l *tokenBucketLimiter) Close() error {
+	close(l.stop)
+	<-l.done
+	return nil
+}
@@ -0,0 +1,280 @@
+package floodcontrol
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Storage хранит метки времени запросов по каждому userID и атомарно
+// совмещает чистку устаревших меток с проверкой и добавлением новой —
+// это то, что раньше slidingLogLimiter делал напрямую поверх
+// map[int64]*list.List. Благодаря Storage несколько реплик сервиса могут
+// делить одно и то же состояние (см. redisStorage).
+type Storage interface {
+	// CheckAndAdd удаляет из истории userID все метки старше now.Add(-window),
+	// и, если оставшихся меньше limit, атомарно добавляет now и возвращает true.
+	CheckAndAdd(ctx context.Context, userID int64, now time.Time, window time.Duration, limit int) (allowed bool, err error)
+	// Oldest возвращает время самого старого запроса userID в истории,
+	// чтобы вызывающая сторона могла посчитать retryAfter.
+	Oldest(ctx context.Context, userID int64) (ts time.Time, ok bool, err error)
+	// Close освобождает ресурсы хранилища (соединения и т. п.).
+	Close() error
+}
+
+// defaultMaxUsers — сколько пользователей memoryStorage помнит одновременно,
+// если явный лимит не задан (см. WithMaxUsers). Раньше requests рос
+// неограниченно — каждый userID, когда-либо обратившийся в Check, навсегда
+// оставался в карте.
+const defaultMaxUsers = 100_000
+
+// defaultSweepInterval — как часто фоновый sweeper ищет простаивающих
+// пользователей, см. sweepLoop.
+const defaultSweepInterval = time.Minute
+
+// memoryStorage — реализация Storage в памяти процесса, используется по
+// умолчанию и годится только для однопроцессных развёртываний. Ограничена
+// по числу одновременно отслеживаемых пользователей (LRU-вытеснение) и
+// чистит простаивающих пользователей фоновым sweeper'ом, чтобы users не
+// рос неограниченно.
+type memoryStorage struct {
+	mu       sync.Mutex
+	users    map[int64]*list.Element // userID -> элемент lru
+	lru      *list.List              // порядок использования, Front — самый недавний
+	maxUsers int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// memoryUserState — история запросов одного пользователя плюс то, что нужно
+// sweepLoop, чтобы понять, простаивает ли пользователь: last seen и окно,
+// с которым он последний раз обращался.
+type memoryUserState struct {
+	userID   int64
+	history  *list.List
+	lastSeen time.Time
+	window   time.Duration
+}
+
+// newMemoryStorage создаёт хранилище с лимитом в maxUsers одновременно
+// отслеживаемых пользователей; maxUsers <= 0 означает defaultMaxUsers.
+func newMemoryStorage(maxUsers int) *memoryStorage {
+	if maxUsers <= 0 {
+		maxUsers = defaultMaxUsers
+	}
+	s := &memoryStorage{
+		users:    make(map[int64]*list.Element),
+		lru:      list.New(),
+		maxUsers: maxUsers,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.sweepLoop(defaultSweepInterval)
+	return s
+}
+
+func (s *memoryStorage) CheckAndAdd(_ context.Context, userID int64, now time.Time, window time.Duration, limit int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.touch(userID, now, window).history
+
+	for front := history.Front(); front != nil; {
+		value, ok := front.Value.(time.Time)
+		if !ok {
+			return false, errNotTime
+		}
+		if now.Sub(value) <= window {
+			break
+		}
+		next := front.Next()
+		history.Remove(front)
+		front = next
+	}
+
+	if history.Len() >= limit {
+		return false, nil
+	}
+
+	history.PushBack(now)
+
+	return true, nil
+}
+
+func (s *memoryStorage) Oldest(_ context.Context, userID int64) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.users[userID]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	front := elem.Value.(*memoryUserState).history.Front()
+	if front == nil {
+		return time.Time{}, false, nil
+	}
+
+	oldest, ok := front.Value.(time.Time)
+	if !ok {
+		return time.Time{}, false, errNotTime
+	}
+	return oldest, true, nil
+}
+
+// touch отмечает userID как недавно использованного, создавая его состояние
+// при первом обращении, и вытесняет наименее недавно использованного
+// пользователя, если из-за этого maxUsers оказался превышен. Вызывается
+// с удержанным s.mu.
+func (s *memoryStorage) touch(userID int64, now time.Time, window time.Duration) *memoryUserState {
+	if elem, ok := s.users[userID]; ok {
+		s.lru.MoveToFront(elem)
+		state := elem.Value.(*memoryUserState)
+		state.lastSeen = now
+		state.window = window
+		return state
+	}
+
+	state := &memoryUserState{userID: userID, history: list.New(), lastSeen: now, window: window}
+	s.users[userID] = s.lru.PushFront(state)
+
+	if s.lru.Len() > s.maxUsers {
+		s.evictOldest()
+	}
+
+	return state
+}
+
+// evictOldest удаляет наименее недавно использованного пользователя.
+// Вызывается с удержанным s.mu.
+func (s *memoryStorage) evictOldest() {
+	back := s.lru.Back()
+	if back == nil {
+		return
+	}
+	s.lru.Remove(back)
+	delete(s.users, back.Value.(*memoryUserState).userID)
+}
+
+// sweepLoop периодически удаляет пользователей, не обращавшихся дольше
+// собственного последнего окна — их история запросов к этому моменту всё
+// равно уже полностью устарела, так что это не меняет поведение Check,
+// а только освобождает память раньше, чем это сделало бы LRU-вытеснение.
+func (s *memoryStorage) sweepLoop(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.sweep(now)
+		}
+	}
+}
+
+func (s *memoryStorage) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for elem := s.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		state := elem.Value.(*memoryUserState)
+		if now.Sub(state.lastSeen) > state.window {
+			s.lru.Remove(elem)
+			delete(s.users, state.userID)
+		}
+		elem = prev
+	}
+}
+
+func (s *memoryStorage) Close() error {
+	close(s.stop)
+	<-s.done
+	return nil
+}
+
+// redisStorage хранит историю запросов в отсортированном множестве Redis
+// (один ключ на пользователя, score — время запроса в наносекундах, member —
+// то же время с порядковым номером, см. checkAndAddScript),
+// что позволяет нескольким репликам сервиса делить одно состояние.
+type redisStorage struct {
+	client    *redis.Client
+	keyPrefix string
+	checkLua  *redis.Script
+}
+
+// checkAndAddScript атомарно чистит устаревшие записи и добавляет новую,
+// чтобы гонка между ZCARD и ZADD не давала пропускать лишние запросы при
+// параллельных обращениях нескольких реплик к одному userID. member для
+// ZADD собирается из метки времени и счётчика KEYS[2], а не из самой метки:
+// два запроса, попавшие в одну и ту же наносекунду, иначе легли бы в
+// множество одним и тем же элементом и ZCARD не увеличился бы.
+var checkAndAddScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[2])
+local count = redis.call('ZCARD', KEYS[1])
+if count >= tonumber(ARGV[3]) then
+	return 0
+end
+local seq = redis.call('INCR', KEYS[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[1] .. '-' .. seq)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+// newRedisStorage оборачивает уже настроенный клиент Redis в Storage.
+// keyPrefix позволяет нескольким флуд-контролям делить один Redis без
+// пересечения ключей пользователей.
+func newRedisStorage(client *redis.Client, keyPrefix string) *redisStorage {
+	return &redisStorage{
+		client:    client,
+		keyPrefix: keyPrefix,
+		checkLua:  checkAndAddScript,
+	}
+}
+
+func (s *redisStorage) key(userID int64) string {
+	return fmt.Sprintf("%s:%d", s.keyPrefix, userID)
+}
+
+// seqKey — ключ счётчика, из которого checkAndAddScript строит уникальный
+// member для ZADD (см. checkAndAddScript).
+func (s *redisStorage) seqKey(userID int64) string {
+	return s.key(userID) + ":seq"
+}
+
+func (s *redisStorage) CheckAndAdd(ctx context.Context, userID int64, now time.Time, window time.Duration, limit int) (bool, error) {
+	windowStartNanos := now.Add(-window).UnixNano()
+	ttlMillis := window.Milliseconds() + 1
+
+	res, err := s.checkLua.Run(ctx, s.client, []string{s.key(userID), s.seqKey(userID)},
+		now.UnixNano(), windowStartNanos, limit, ttlMillis).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+func (s *redisStorage) Oldest(ctx context.Context, userID int64) (time.Time, bool, error) {
+	values, err := s.client.ZRangeWithScores(ctx, s.key(userID), 0, 0).Result()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(values) == 0 {
+		return time.Time{}, false, nil
+	}
+	return time.Unix(0, int64(values[0].Score)), true, nil
+}
+
+func (s *redisStorage) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,121 @@
+package floodcontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisStorage поднимает miniredis и оборачивает его в redisStorage,
+// чтобы проверить checkAndAddScript без настоящего Redis.
+func newTestRedisStorage(t *testing.T) *redisStorage {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return newRedisStorage(client, "flood_control_test")
+}
+
+func TestRedisStorage_CheckAndAdd(t *testing.T) {
+	s := newTestRedisStorage(t)
+	ctx := context.Background()
+	userID := int64(1)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		ok, err := s.CheckAndAdd(ctx, userID, now, time.Minute, 3)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !ok {
+			t.Errorf("запрос %d должен пройти", i)
+		}
+	}
+
+	ok, err := s.CheckAndAdd(ctx, userID, now, time.Minute, 3)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if ok {
+		t.Error("четвёртый запрос должен быть отклонён лимитом")
+	}
+}
+
+func TestRedisStorage_CheckAndAdd_ExpiresOldEntries(t *testing.T) {
+	s := newTestRedisStorage(t)
+	ctx := context.Background()
+	userID := int64(1)
+	window := time.Minute
+
+	ok, err := s.CheckAndAdd(ctx, userID, time.Now().Add(-2*window), window, 1)
+	if err != nil || !ok {
+		t.Fatalf("первый запрос должен пройти: ok=%v err=%v", ok, err)
+	}
+
+	ok, err = s.CheckAndAdd(ctx, userID, time.Now(), window, 1)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !ok {
+		t.Error("запрос вне окна старой метки должен пройти — старая метка устарела")
+	}
+}
+
+func TestRedisStorage_CheckAndAdd_SameNanosecondDoesNotCollide(t *testing.T) {
+	s := newTestRedisStorage(t)
+	ctx := context.Background()
+	userID := int64(1)
+	now := time.Now()
+
+	// оба запроса с одной и той же меткой времени раньше ложились бы в ZADD
+	// одним и тем же member'ом и второй не увеличивал бы счётчик, см. seqKey.
+	ok1, err := s.CheckAndAdd(ctx, userID, now, time.Minute, 2)
+	if err != nil || !ok1 {
+		t.Fatalf("первый запрос должен пройти: ok=%v err=%v", ok1, err)
+	}
+	ok2, err := s.CheckAndAdd(ctx, userID, now, time.Minute, 2)
+	if err != nil || !ok2 {
+		t.Fatalf("второй запрос с той же меткой времени должен пройти: ok=%v err=%v", ok2, err)
+	}
+
+	ok3, err := s.CheckAndAdd(ctx, userID, now, time.Minute, 2)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if ok3 {
+		t.Error("третий запрос должен быть отклонён лимитом — значит первые два посчитались как два разных запроса")
+	}
+}
+
+func TestRedisStorage_Oldest(t *testing.T) {
+	s := newTestRedisStorage(t)
+	ctx := context.Background()
+	userID := int64(1)
+
+	if _, ok, err := s.Oldest(ctx, userID); err != nil || ok {
+		t.Fatalf("без истории Oldest не должен ничего находить: ok=%v err=%v", ok, err)
+	}
+
+	now := time.Now()
+	if ok, err := s.CheckAndAdd(ctx, userID, now, time.Minute, 5); err != nil || !ok {
+		t.Fatalf("запрос должен пройти: ok=%v err=%v", ok, err)
+	}
+
+	oldest, ok, err := s.Oldest(ctx, userID)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !ok {
+		t.Fatal("после CheckAndAdd Oldest должен найти метку")
+	}
+	// score в Redis — float64, поэтому наносекундная метка теряет немного
+	// точности при обратном преобразовании; сравниваем с допуском.
+	if diff := oldest.Sub(now); diff < -time.Microsecond || diff > time.Microsecond {
+		t.Errorf("Oldest = %v, хотим %v (разница %v)", oldest, now, diff)
+	}
+}
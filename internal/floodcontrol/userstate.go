@@ -0,0 +1,105 @@
+package floodcontrol
+
+import (
+	"container/list"
+	"time"
+)
+
+// boundedUserMap хранит по одному значению T на userID с LRU-вытеснением
+// при превышении maxUsers — тот же приём, которым memoryStorage решает
+// проблему неограниченно растущей карты пользователей (см. storage.go),
+// только переиспользуемый для лимитеров (limiter.go) и CircuitBreaker,
+// у которых своя карта состояний на userID. Не потокобезопасна сама по
+// себе: вызывающая сторона обязана удерживать собственный мьютекс на
+// каждую операцию, как уже делают все лимитеры и CircuitBreaker.
+type boundedUserMap[T any] struct {
+	items    map[int64]*list.Element
+	lru      *list.List // порядок использования, Front — самый недавний
+	maxUsers int
+}
+
+type boundedUserEntry[T any] struct {
+	userID   int64
+	value    T
+	lastSeen time.Time
+}
+
+// newBoundedUserMap создаёт карту с лимитом в maxUsers одновременно
+// отслеживаемых пользователей; maxUsers <= 0 означает defaultMaxUsers.
+func newBoundedUserMap[T any](maxUsers int) *boundedUserMap[T] {
+	if maxUsers <= 0 {
+		maxUsers = defaultMaxUsers
+	}
+	return &boundedUserMap[T]{
+		items:    make(map[int64]*list.Element),
+		lru:      list.New(),
+		maxUsers: maxUsers,
+	}
+}
+
+// getOrCreate возвращает состояние userID, создавая его через create() при
+// первом обращении, отмечает пользователя как недавно использованного и
+// вытесняет наименее недавно использованного, если из-за этого maxUsers
+// оказался превышен.
+func (m *boundedUserMap[T]) getOrCreate(userID int64, now time.Time, create func() T) *T {
+	if elem, ok := m.items[userID]; ok {
+		m.lru.MoveToFront(elem)
+		entry := elem.Value.(*boundedUserEntry[T])
+		entry.lastSeen = now
+		return &entry.value
+	}
+
+	entry := &boundedUserEntry[T]{userID: userID, value: create(), lastSeen: now}
+	m.items[userID] = m.lru.PushFront(entry)
+
+	if m.lru.Len() > m.maxUsers {
+		m.evictOldest()
+	}
+
+	return &entry.value
+}
+
+// evictOldest удаляет наименее недавно использованного пользователя.
+func (m *boundedUserMap[T]) evictOldest() {
+	back := m.lru.Back()
+	if back == nil {
+		return
+	}
+	m.lru.Remove(back)
+	delete(m.items, back.Value.(*boundedUserEntry[T]).userID)
+}
+
+// sweep удаляет пользователей, не обращавшихся дольше ttl — освобождает
+// память раньше, чем это сделало бы LRU-вытеснение, не меняя поведение
+// getOrCreate.
+func (m *boundedUserMap[T]) sweep(now time.Time, ttl time.Duration) {
+	for elem := m.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		entry := elem.Value.(*boundedUserEntry[T])
+		if now.Sub(entry.lastSeen) > ttl {
+			m.lru.Remove(elem)
+			delete(m.items, entry.userID)
+		}
+		elem = prev
+	}
+}
+
+// sweeper запускает f каждые interval в отдельной горутине, пока не будет
+// остановлен через stop; f сам решает, что и как чистить (см. использование
+// в лимитерах и CircuitBreaker). done закрывается по выходу из горутины,
+// чтобы Close мог дождаться её завершения.
+func sweeper(stop <-chan struct{}, done chan<- struct{}, interval time.Duration, f func(now time.Time)) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			f(now)
+		}
+	}
+}
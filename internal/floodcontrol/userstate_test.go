@@ -0,0 +1,45 @@
+package floodcontrol
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoundedUserMap_EvictsLeastRecentlyUsed(t *testing.T) {
+	m := newBoundedUserMap[int](2)
+	now := time.Now()
+
+	m.getOrCreate(1, now, func() int { return 1 })
+	m.getOrCreate(2, now, func() int { return 2 })
+	m.getOrCreate(3, now, func() int { return 3 }) // вытесняет userID 1
+
+	created := false
+	m.getOrCreate(1, now, func() int { created = true; return 1 }) // вытесняет userID 2 как наименее недавно использованного
+	if !created {
+		t.Error("userID 1 должен был быть вытеснен и создан заново")
+	}
+
+	if _, ok := m.items[3]; !ok {
+		t.Error("userID 3 не должен был быть вытеснен")
+	}
+	if _, ok := m.items[2]; ok {
+		t.Error("userID 2 должен был быть вытеснен как наименее недавно использованный")
+	}
+}
+
+func TestBoundedUserMap_Sweep(t *testing.T) {
+	m := newBoundedUserMap[int](10)
+	now := time.Now()
+
+	m.getOrCreate(1, now, func() int { return 1 })
+	m.getOrCreate(2, now.Add(time.Minute), func() int { return 2 })
+
+	m.sweep(now.Add(2*time.Minute), time.Minute)
+
+	if _, ok := m.items[1]; ok {
+		t.Error("простаивающий дольше ttl userID 1 должен быть удалён sweep'ом")
+	}
+	if _, ok := m.items[2]; !ok {
+		t.Error("userID 2 обращался позже, не должен быть удалён")
+	}
+}
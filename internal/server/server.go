@@ -0,0 +1,193 @@
+// Package server оборачивает floodcontrol.FloodControl в gRPC-сервис и
+// HTTP-мидлварь, которую можно подключить перед Telegram-ботом или REST API,
+// добавляет метрики Prometheus, /healthz и аккуратное завершение работы —
+// то, чего не даёт CLI из корневого main.go.
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"flood-control-task/proto/floodcontrolpb"
+)
+
+// Checker — то немногое, что нужно от floodcontrol.FloodControlImpl
+// серверу: проверка лимита с возвратом времени до повторной попытки.
+// Реализуется *floodcontrol.FloodControlImpl без изменений.
+type Checker interface {
+	Reserve(ctx context.Context, userID int64) (allowed bool, retryAfter time.Duration, err error)
+}
+
+var (
+	checkTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flood_check_total",
+		Help: "Количество обращений к FloodControl по результату (allowed/throttled/error).",
+	}, []string{"result"})
+
+	userThrottledTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "flood_user_throttled_total",
+		Help: "Количество отклонённых запросов. Без разбивки по пользователю: userID " +
+			"не ограничен по количеству значений и раздул бы кардинальность метрики " +
+			"до одной серии на каждого когда-либо отклонённого пользователя.",
+	})
+
+	checkDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "flood_check_duration_seconds",
+		Help:    "Длительность проверки FloodControl.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+func init() {
+	prometheus.MustRegister(checkTotal, userThrottledTotal, checkDuration)
+}
+
+// observe фиксирует метрики одной проверки: длительность и результат
+// (allowed/throttled/error).
+func observe(allowed bool, err error, elapsed time.Duration) {
+	checkDuration.Observe(elapsed.Seconds())
+
+	result := "allowed"
+	switch {
+	case err != nil:
+		result = "error"
+	case !allowed:
+		result = "throttled"
+		userThrottledTotal.Inc()
+	}
+	checkTotal.WithLabelValues(result).Inc()
+}
+
+// GRPCServer реализует floodcontrolpb.FloodControlServer поверх Checker.
+type GRPCServer struct {
+	floodcontrolpb.UnimplementedFloodControlServer
+	checker Checker
+}
+
+// NewGRPCServer оборачивает checker в gRPC-сервис FloodControl.
+func NewGRPCServer(checker Checker) *GRPCServer {
+	return &GRPCServer{checker: checker}
+}
+
+func (s *GRPCServer) Check(ctx context.Context, req *floodcontrolpb.CheckRequest) (*floodcontrolpb.CheckResponse, error) {
+	start := time.Now()
+	allowed, retryAfter, err := s.checker.Reserve(ctx, req.GetUserId())
+	observe(allowed, err, time.Since(start))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &floodcontrolpb.CheckResponse{Allowed: allowed}
+	if !allowed {
+		resp.RetryAfter = durationpb.New(retryAfter)
+	}
+	return resp, nil
+}
+
+// UserIDFunc извлекает userID из входящего HTTP-запроса — из заголовка,
+// JWT-клейма или IP, в зависимости от того, что решит вызывающая сторона.
+type UserIDFunc func(r *http.Request) (int64, error)
+
+// HeaderUserID — готовый UserIDFunc, читающий userID из заголовка header.
+func HeaderUserID(header string) UserIDFunc {
+	return func(r *http.Request) (int64, error) {
+		value := r.Header.Get(header)
+		if value == "" {
+			return 0, errors.New("отсутствует заголовок " + header)
+		}
+		return strconv.ParseInt(value, 10, 64)
+	}
+}
+
+// Middleware оборачивает next, отклоняя запросы, превысившие лимит, кодом
+// 429 и заголовком Retry-After. userID определяет, как извлечь
+// идентификатора пользователя из запроса (см. HeaderUserID).
+func Middleware(checker Checker, userID UserIDFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := userID(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			start := time.Now()
+			allowed, retryAfter, err := checker.Reserve(r.Context(), id)
+			observe(allowed, err, time.Since(start))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+				http.Error(w, "превышено максимальное количество запросов", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Server запускает gRPC и HTTP (метрики + /healthz) серверы флуд-контроля
+// и умеет аккуратно останавливаться по отмене контекста.
+type Server struct {
+	GRPCAddr string
+	HTTPAddr string
+	Checker  Checker
+
+	grpcServer *grpc.Server
+	httpServer *http.Server
+}
+
+// Run запускает оба сервера и блокируется, пока ctx не будет отменён или
+// один из серверов не завершится с ошибкой. При отмене ctx gRPC
+// останавливается через GracefulStop, HTTP — через Shutdown.
+func (s *Server) Run(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.GRPCAddr)
+	if err != nil {
+		return err
+	}
+
+	s.grpcServer = grpc.NewServer()
+	floodcontrolpb.RegisterFloodControlServer(s.grpcServer, NewGRPCServer(s.Checker))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.Handle("/metrics", promhttp.Handler())
+	s.httpServer = &http.Server{Addr: s.HTTPAddr, Handler: mux}
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- s.grpcServer.Serve(lis)
+	}()
+	go func() {
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		s.grpcServer.GracefulStop()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return s.httpServer.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		return err
+	}
+}
@@ -0,0 +1,125 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"flood-control-task/internal/server"
+	"flood-control-task/proto/floodcontrolpb"
+)
+
+// stubChecker — фиктивный server.Checker с заранее заданным ответом,
+// чтобы тестировать HTTP/gRPC-обвязку отдельно от floodcontrol.FloodControlImpl.
+type stubChecker struct {
+	allowed    bool
+	retryAfter time.Duration
+	err        error
+}
+
+func (c stubChecker) Reserve(ctx context.Context, userID int64) (bool, time.Duration, error) {
+	return c.allowed, c.retryAfter, c.err
+}
+
+func TestMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cases := []struct {
+		name           string
+		checker        server.Checker
+		userID         server.UserIDFunc
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:       "разрешённый запрос пропускается дальше",
+			checker:    stubChecker{allowed: true},
+			userID:     server.HeaderUserID("X-User-Id"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:           "отклонённый запрос отвечает 429 с Retry-After",
+			checker:        stubChecker{allowed: false, retryAfter: 5 * time.Second},
+			userID:         server.HeaderUserID("X-User-Id"),
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "5",
+		},
+		{
+			name:       "отсутствие userID отвечает 400",
+			checker:    stubChecker{allowed: true},
+			userID:     func(r *http.Request) (int64, error) { return 0, errors.New("нет userID") },
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "ошибка Checker отвечает 500",
+			checker:    stubChecker{err: errors.New("хранилище недоступно")},
+			userID:     server.HeaderUserID("X-User-Id"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := server.Middleware(tc.checker, tc.userID)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User-Id", "42")
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("код ответа = %d, ожидался %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantRetryAfter != "" && rec.Header().Get("Retry-After") != tc.wantRetryAfter {
+				t.Errorf("Retry-After = %q, ожидался %q", rec.Header().Get("Retry-After"), tc.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestGRPCServer_Check(t *testing.T) {
+	t.Run("разрешённый запрос", func(t *testing.T) {
+		s := server.NewGRPCServer(stubChecker{allowed: true})
+
+		resp, err := s.Check(context.Background(), &floodcontrolpb.CheckRequest{UserId: 1})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !resp.GetAllowed() {
+			t.Error("Allowed должен быть true")
+		}
+	})
+
+	t.Run("отклонённый запрос возвращает RetryAfter", func(t *testing.T) {
+		s := server.NewGRPCServer(stubChecker{allowed: false, retryAfter: 3 * time.Second})
+
+		resp, err := s.Check(context.Background(), &floodcontrolpb.CheckRequest{UserId: 2})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if resp.GetAllowed() {
+			t.Error("Allowed должен быть false")
+		}
+		if resp.GetRetryAfter().AsDuration() != 3*time.Second {
+			t.Errorf("RetryAfter = %v, ожидалось 3s", resp.GetRetryAfter().AsDuration())
+		}
+	})
+
+	t.Run("ошибка Checker превращается в codes.Internal", func(t *testing.T) {
+		s := server.NewGRPCServer(stubChecker{err: errors.New("хранилище недоступно")})
+
+		_, err := s.Check(context.Background(), &floodcontrolpb.CheckRequest{UserId: 3})
+		if status.Code(err) != codes.Internal {
+			t.Errorf("код ошибки = %v, ожидался %v", status.Code(err), codes.Internal)
+		}
+	})
+}
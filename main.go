@@ -1,74 +1,19 @@
+// Command main — справочный CLI поверх flood-control-task/internal/floodcontrol:
+// читает userID из stdin и печатает результат проверки. Для реального
+// развёртывания используется cmd/floodcontrold (см. internal/server).
 package main
 
 import (
-	"container/list"
 	"context"
-	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
-	"sync"
+	"syscall"
 	"time"
-)
-
-// Изначальный интерфейс
-type FloodControl interface {
-	Check(ctx context.Context, userID int64) (bool, error)
-}
-
-// реализация интерфейса FloodControl.
-type FloodControlImpl struct {
-	mu           sync.Mutex           // мьютекс, чтобы данные в функцию не летели из разных экземпляров приложения одновременно, не вызывали проблемы в...
-	requests     map[int64]*list.List // кэшэ, который хранит запросы за...
-	timeInterval time.Duration        // интервал времени (N секунд)
-	maxRequests  int                  // максимальное количество запросов (K запросов или вызовов функций)
-}
-
-// функция для создания нового флудконтрола
-func NewFloodControl(timeInterval time.Duration, maxRequests int) *FloodControlImpl {
-	return &FloodControlImpl{
-		requests:     make(map[int64]*list.List), // создаем пустой кэш запросов
-		timeInterval: timeInterval,               // задаем интервал времени (N)
-		maxRequests:  maxRequests,                // задаем максимальное количество вызовов функций (K)
-	}
-}
-
-func (fc *FloodControlImpl) Check(ctx context.Context, userID int64) (bool, error) {
-	fc.mu.Lock() // лочим и анлочим мьютекс, чтобы кэш не переполнялся
-	defer fc.mu.Unlock()
 
-	now := time.Now() // смотрим какое сейчас время
-
-	// чистим старые запросы
-	requestTimes := fc.requests[userID]
-	if requestTimes == nil { // пользователь не совершал запросы, после запуска флуд контроля
-        requestTimes = list.New()
-    }
-
-    for front := requestTimes.Front(); front != nil; {
-        value, ok := front.Value.(time.Time) // получаем самый старый запрос
-        if !ok { // почему-то в очереди хранится не время
-            return false, errors.New("в очереди хранится не время")
-        }
-        if now.Sub(value) <= fc.timeInterval { // запрос не старее 10 секунд
-            break
-        }
-        next := front.Next() // идем дальше
-        requestTimes.Remove(front) // удаляем старый запрос
-        front = next // изменяем указатель на первый элемент
-    }
-
-	// смотрим, сколько запросов пришло
-	if requestTimes.Len() >= fc.maxRequests {
-		return false, errors.New("превышено максимальное количество запросов") // запросов >= K
-	}
-
-	// дописываем текущий обработанный запрос в кэш
-	requestTimes.PushBack(now)
-	fc.requests[userID] = requestTimes
-
-	return true, nil
-}
+	"flood-control-task/internal/floodcontrol"
+)
 
 func main() {
 	args := os.Args
@@ -96,9 +41,13 @@ func main() {
 		return
 	}
 
-	fc := NewFloodControl(time.Second*time.Duration(N), K) // проверка на флуд за последние N секунд, максимум K запросов.
+	fc := floodcontrol.NewFloodControl(time.Second*time.Duration(N), K) // проверка на флуд за последние N секунд, максимум K запросов.
+	defer fc.Close()                                                    // закрываем хранилище (соединение с Redis и т. п.) при выходе
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	for {
+	for ctx.Err() == nil {
 		// допустим, что общение между программами "глупое"
 		// они просто пишут в терминал с запущенным флуд-контролем
 		// и считывают ответ
@@ -106,6 +55,6 @@ func main() {
 		// и будут ходить в него, но по ТЗ это не просится реализовывать
 		var userID int
 		fmt.Scan(&userID)
-		fmt.Println(fc.Check(context.Background(), int64(userID)))
+		fmt.Println(fc.Check(ctx, int64(userID)))
 	}
 }